@@ -18,6 +18,15 @@ type Fronius struct {
 	Client  *http.Client
 }
 
+// Backend is implemented by each supported way of talking to an inverter.
+// The Solar API client (Fronius) and the Modbus TCP/SunSpec client (Modbus)
+// both satisfy it, so the collector can work against either unchanged.
+type Backend interface {
+	GetInverterInfo(ctx context.Context) ([]*InverterInfo, error)
+	GetInverterRealtimeCommonData(ctx context.Context, deviceID string) (*InverterCommonData, error)
+	GetInverterRealtimeThreePhaseData(ctx context.Context, deviceID string) (*InverterThreePhaseData, error)
+}
+
 func NewFronius(urlString string) (*Fronius, error) {
 	u, err := url.Parse(urlString)
 	if err != nil {
@@ -33,21 +42,33 @@ func NewFronius(urlString string) (*Fronius, error) {
 type StatusCode int
 
 const (
-	StatusCodeStartup     = "Startup"
-	StatusCodeRunning     = "Running"
-	StatusCodeStandby     = "Standby"
-	StatusCodeBootloading = "Bootloading"
-	StatusCodeError       = "Error"
-	StatusCodeIdle        = "Idle"
-	StatusCodeReady       = "Ready"
-	StatusCodeSleeping    = "Sleeping"
-	StatusCodeUnknown     = "Unknown"
-	StatusCodeInvalid     = "INVALID"
+	StatusCodeUp           = "Up"
+	StatusCodeOff          = "Off"
+	StatusCodeAutoShutdown = "AutoShutdown"
+	StatusCodeStartup      = "Startup"
+	StatusCodeStartup2     = "Startup2"
+	StatusCodeStartup3     = "Startup3"
+	StatusCodeStartup4     = "Startup4"
+	StatusCodeRunning      = "Running"
+	StatusCodeStandby      = "Standby"
+	StatusCodeBootloading  = "Bootloading"
+	StatusCodeError        = "Error"
+	StatusCodeIdle         = "Idle"
+	StatusCodeReady        = "Ready"
+	StatusCodeSleeping     = "Sleeping"
+	StatusCodeUnknown      = "Unknown"
+	StatusCodeInvalid      = "INVALID"
 )
 
 func StatusCodes() []string {
 	return []string{
+		StatusCodeUp,
+		StatusCodeOff,
+		StatusCodeAutoShutdown,
 		StatusCodeStartup,
+		StatusCodeStartup2,
+		StatusCodeStartup3,
+		StatusCodeStartup4,
 		StatusCodeRunning,
 		StatusCodeStandby,
 		StatusCodeBootloading,
@@ -60,32 +81,41 @@ func StatusCodes() []string {
 	}
 }
 
+// String implements the Fronius Solar API status code table. Codes 0-6 are
+// distinct sub-states of the inverter's off/startup sequence and must not be
+// collapsed into one another, otherwise "off overnight" becomes
+// indistinguishable from "booting".
 func (i StatusCode) String() string {
-	if i >= 0 && i < 7 {
+	switch i {
+	case 0:
+		return StatusCodeUp
+	case 1:
+		return StatusCodeOff
+	case 2:
+		return StatusCodeAutoShutdown
+	case 3:
 		return StatusCodeStartup
-	}
-	if i == 7 {
+	case 4:
+		return StatusCodeStartup2
+	case 5:
+		return StatusCodeStartup3
+	case 6:
+		return StatusCodeStartup4
+	case 7:
 		return StatusCodeRunning
-	}
-	if i == 8 {
+	case 8:
 		return StatusCodeStandby
-	}
-	if i == 9 {
+	case 9:
 		return StatusCodeBootloading
-	}
-	if i == 10 {
+	case 10:
 		return StatusCodeError
-	}
-	if i == 11 {
+	case 11:
 		return StatusCodeIdle
-	}
-	if i == 12 {
+	case 12:
 		return StatusCodeReady
-	}
-	if i == 13 {
+	case 13:
 		return StatusCodeSleeping
-	}
-	if i == 255 {
+	case 255:
 		return StatusCodeUnknown
 	}
 	return StatusCodeInvalid
@@ -295,4 +325,222 @@ func (f *Fronius) GetInverterRealtimeThreePhaseData(ctx context.Context, deviceI
 	return &threePhaseData, nil
 }
 
-// GetRealtimePowerFlowRealtimeData
+// PowerFlowSite is the household-level summary returned by
+// GetPowerFlowRealtimeData, covering generation, consumption, storage and
+// grid exchange in one shot.
+type PowerFlowSite struct {
+	// Mode describes how the site is wired, e.g. "meter" or "vague".
+	Mode string `json:"Mode"`
+	// PGrid is grid power in watts; positive means import, negative export.
+	PGrid float64 `json:"P_Grid"`
+	// PLoad is household load in watts (negative, by Fronius convention).
+	PLoad float64 `json:"P_Load"`
+	// PAkku is battery power in watts; positive means discharging.
+	PAkku float64 `json:"P_Akku"`
+	// PPV is PV generation in watts.
+	PPV    float64 `json:"P_PV"`
+	EDay   float64 `json:"E_Day"`
+	EYear  float64 `json:"E_Year"`
+	ETotal float64 `json:"E_Total"`
+	// RelAutonomy is the share of load covered without grid import, in percent.
+	RelAutonomy float64 `json:"rel_Autonomy"`
+	// RelSelfConsumption is the share of PV generation consumed on site, in percent.
+	RelSelfConsumption float64 `json:"rel_SelfConsumption"`
+}
+
+type PowerFlowRealtimeData struct {
+	Site PowerFlowSite `json:"Site"`
+}
+
+// GetPowerFlowRealtimeData reports the combined generation/consumption/grid
+// power flow for the whole site.
+// /solar_api/v1/GetPowerFlowRealtimeData.fcgi
+func (f *Fronius) GetPowerFlowRealtimeData(ctx context.Context) (*PowerFlowRealtimeData, error) {
+	u := f.baseURL
+	u.Path = filepath.Join(u.Path, "GetPowerFlowRealtimeData.fcgi")
+	req, err := f.newRequest(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := f.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected http status: %s", resp.Status)
+	}
+
+	var msg Msg
+	if err := json.NewDecoder(resp.Body).Decode(&msg); err != nil {
+		return nil, fmt.Errorf("error parsing json message: %w", err)
+	}
+	if err := msg.Error(); err != nil {
+		return nil, err
+	}
+
+	var data PowerFlowRealtimeData
+	if err := json.Unmarshal(msg.Body.Data, &data); err != nil {
+		return nil, fmt.Errorf("unable to parse power flow realtime data: %w", err)
+	}
+	return &data, nil
+}
+
+// MeterDetails identifies the physical smart meter a MeterRealtimeData
+// entry was read from.
+type MeterDetails struct {
+	Manufacturer string `json:"Manufacturer"`
+	Model        string `json:"Model"`
+	Serial       string `json:"Serial"`
+}
+
+// MeterRealtimeData is one smart meter as reported by GetMeterRealtimeData,
+// covering imported/exported energy and per-phase voltage/current/power
+// factor.
+type MeterRealtimeData struct {
+	Details MeterDetails `json:"Details"`
+	// MeterLocationCurrent is 0 for the grid interconnection point, 1 for a
+	// load, and a higher value for subloads; see the Solar API spec.
+	MeterLocationCurrent int `json:"Meter_Location_Current"`
+
+	EnergyRealWACPlusAbsolute  float64 `json:"EnergyReal_WAC_Plus_Absolute"`
+	EnergyRealWACMinusAbsolute float64 `json:"EnergyReal_WAC_Minus_Absolute"`
+	PowerRealPSum              float64 `json:"PowerReal_P_Sum"`
+
+	VoltageACPhase1 float64 `json:"Voltage_AC_Phase_1"`
+	VoltageACPhase2 float64 `json:"Voltage_AC_Phase_2"`
+	VoltageACPhase3 float64 `json:"Voltage_AC_Phase_3"`
+
+	CurrentACPhase1 float64 `json:"Current_AC_Phase_1"`
+	CurrentACPhase2 float64 `json:"Current_AC_Phase_2"`
+	CurrentACPhase3 float64 `json:"Current_AC_Phase_3"`
+
+	PowerFactorPhase1 float64 `json:"PowerFactor_Phase_1"`
+	PowerFactorPhase2 float64 `json:"PowerFactor_Phase_2"`
+	PowerFactorPhase3 float64 `json:"PowerFactor_Phase_3"`
+}
+
+// GetMeterRealtimeData lists every smart meter visible to the system.
+// /solar_api/v1/GetMeterRealtimeData.cgi?Scope=System
+func (f *Fronius) GetMeterRealtimeData(ctx context.Context) (map[string]*MeterRealtimeData, error) {
+	u := f.baseURL
+	u.Path = filepath.Join(u.Path, "GetMeterRealtimeData.cgi")
+	u.RawQuery = url.Values{
+		"Scope": []string{"System"},
+	}.Encode()
+	req, err := f.newRequest(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := f.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected http status: %s", resp.Status)
+	}
+
+	var msg Msg
+	if err := json.NewDecoder(resp.Body).Decode(&msg); err != nil {
+		return nil, fmt.Errorf("error parsing json message: %w", err)
+	}
+	if err := msg.Error(); err != nil {
+		return nil, err
+	}
+
+	meters := make(map[string]*MeterRealtimeData)
+	if err := json.Unmarshal(msg.Body.Data, &meters); err != nil {
+		return nil, fmt.Errorf("unable to parse meter realtime data: %w", err)
+	}
+	return meters, nil
+}
+
+// StorageDetails identifies the battery/storage hardware a controller or
+// module belongs to.
+type StorageDetails struct {
+	Manufacturer string `json:"Manufacturer"`
+	Model        string `json:"Model"`
+	Serial       string `json:"Serial"`
+}
+
+// StorageController is the battery inverter/BMS controller reported for a
+// storage system, e.g. the Symo Hybrid's built-in controller.
+type StorageController struct {
+	Details StorageDetails `json:"Details"`
+	Enable  int            `json:"Enable"`
+
+	StateOfChargeRelative float64 `json:"StateOfCharge_Relative"`
+	CapacityMaximum       float64 `json:"Capacity_Maximum"`
+	DesignedCapacity      float64 `json:"DesignedCapacity"`
+	VoltageDC             float64 `json:"Voltage_DC"`
+	CurrentDC             float64 `json:"Current_DC"`
+	TemperatureCell       float64 `json:"Temperature_Cell"`
+	StatusBatteryCell     int     `json:"Status_BatteryCell"`
+}
+
+// StorageModule is one physical battery module behind a controller, e.g. a
+// single BYD HVS tower.
+type StorageModule struct {
+	Details StorageDetails `json:"Details"`
+	Enable  int            `json:"Enable"`
+
+	StateOfChargeRelative float64 `json:"StateOfCharge_Relative"`
+	DesignedCapacity      float64 `json:"DesignedCapacity"`
+	VoltageDC             float64 `json:"Voltage_DC"`
+	CurrentDC             float64 `json:"Current_DC"`
+	TemperatureCell       float64 `json:"Temperature_Cell"`
+	CycleCountBatteryCell int     `json:"CycleCount_BatteryCell"`
+	StatusBatteryCell     int     `json:"Status_BatteryCell"`
+}
+
+// StorageRealtimeData is one storage system as reported by
+// GetStorageRealtimeData, covering its controller and, where the inverter
+// exposes it, the individual battery modules behind it.
+type StorageRealtimeData struct {
+	Controller StorageController `json:"Controller"`
+	Modules    []StorageModule   `json:"Modules"`
+}
+
+// GetStorageRealtimeData lists every battery/storage system visible to the
+// system.
+// /solar_api/v1/GetStorageRealtimeData.cgi?Scope=System
+func (f *Fronius) GetStorageRealtimeData(ctx context.Context) (map[string]*StorageRealtimeData, error) {
+	u := f.baseURL
+	u.Path = filepath.Join(u.Path, "GetStorageRealtimeData.cgi")
+	u.RawQuery = url.Values{
+		"Scope": []string{"System"},
+	}.Encode()
+	req, err := f.newRequest(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := f.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected http status: %s", resp.Status)
+	}
+
+	var msg Msg
+	if err := json.NewDecoder(resp.Body).Decode(&msg); err != nil {
+		return nil, fmt.Errorf("error parsing json message: %w", err)
+	}
+	if err := msg.Error(); err != nil {
+		return nil, err
+	}
+
+	storages := make(map[string]*StorageRealtimeData)
+	if err := json.Unmarshal(msg.Body.Data, &storages); err != nil {
+		return nil, fmt.Errorf("unable to parse storage realtime data: %w", err)
+	}
+	return storages, nil
+}
@@ -0,0 +1,307 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+
+	"github.com/grid-x/modbus"
+)
+
+// SunSpec well-known model IDs, as defined by the SunSpec Alliance
+// Information Model specification.
+const (
+	sunspecModelCommon          = 1
+	sunspecModelInverterSingle  = 101
+	sunspecModelInverterSplit   = 102
+	sunspecModelInverterThree   = 103
+	sunspecModelMultiMPPT       = 160
+	sunspecModelEnd             = 0xFFFF
+	sunspecBaseRegister         = 40000
+	sunspecIdentifierRegister   = 40000
+	sunspecIdentifierValue      = 0x53756e53 // "SunS"
+	sunspecHeaderRegisterLength = 2
+)
+
+// Modbus talks to a Fronius/Symo/Gen24 inverter over Modbus TCP using the
+// SunSpec information model, as a faster and more reliable alternative to
+// the Solar API scraped by Fronius. It implements the same Backend surface
+// so the collector works unchanged regardless of which backend is selected.
+type Modbus struct {
+	mu      sync.Mutex
+	client  modbus.Client
+	handler *modbus.TCPClientHandler
+
+	// models caches the register offset each discovered SunSpec model
+	// starts at, keyed by model ID, so repeated scrapes don't have to
+	// walk the model chain again.
+	models map[uint16]uint16
+}
+
+// NewModbus dials a Modbus TCP server at address (host:port) and verifies
+// the SunSpec identifier block before returning.
+func NewModbus(ctx context.Context, address string, unitID byte) (*Modbus, error) {
+	handler := modbus.NewTCPClientHandler(address)
+	handler.SlaveID = unitID
+	if err := handler.Connect(ctx); err != nil {
+		return nil, fmt.Errorf("unable to connect to modbus device: %w", err)
+	}
+
+	m := &Modbus{
+		client:  modbus.NewClient(handler),
+		handler: handler,
+		models:  make(map[uint16]uint16),
+	}
+
+	if err := m.checkIdentifier(ctx); err != nil {
+		handler.Close()
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// Close releases the underlying TCP connection.
+func (m *Modbus) Close() error {
+	return m.handler.Close()
+}
+
+func (m *Modbus) checkIdentifier(ctx context.Context) error {
+	regs, err := m.readRegisters(ctx, sunspecIdentifierRegister, sunspecHeaderRegisterLength)
+	if err != nil {
+		return fmt.Errorf("unable to read sunspec identifier: %w", err)
+	}
+	if uint32(regs[0])<<16|uint32(regs[1]) != sunspecIdentifierValue {
+		return fmt.Errorf("device at %s is not a sunspec-compliant device", m.handler.Address)
+	}
+	return nil
+}
+
+func (m *Modbus) readRegisters(ctx context.Context, address, quantity uint16) ([]uint16, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	raw, err := m.client.ReadHoldingRegisters(ctx, address, quantity)
+	if err != nil {
+		return nil, err
+	}
+
+	regs := make([]uint16, quantity)
+	for i := range regs {
+		regs[i] = uint16(raw[i*2])<<8 | uint16(raw[i*2+1])
+	}
+	return regs, nil
+}
+
+// findModel walks the SunSpec model chain starting right after the
+// identifier block, returning the register address of the model's first
+// data point (i.e. just past its ID and length header).
+func (m *Modbus) findModel(ctx context.Context, modelID uint16) (uint16, uint16, error) {
+	if addr, ok := m.models[modelID]; ok {
+		length, err := m.modelLength(ctx, addr)
+		return addr, length, err
+	}
+
+	addr := uint16(sunspecBaseRegister + sunspecHeaderRegisterLength)
+	for {
+		header, err := m.readRegisters(ctx, addr, 2)
+		if err != nil {
+			return 0, 0, fmt.Errorf("unable to read sunspec model header at %d: %w", addr, err)
+		}
+		id, length := header[0], header[1]
+		if id == sunspecModelEnd {
+			return 0, 0, fmt.Errorf("sunspec model %d not found on device", modelID)
+		}
+
+		m.models[id] = addr + 2
+		if id == modelID {
+			return addr + 2, length, nil
+		}
+		addr += 2 + length
+	}
+}
+
+func (m *Modbus) modelLength(ctx context.Context, dataAddr uint16) (uint16, error) {
+	header, err := m.readRegisters(ctx, dataAddr-2, 2)
+	if err != nil {
+		return 0, err
+	}
+	return header[1], nil
+}
+
+// scaled applies a SunSpec signed 16-bit scale factor (as used throughout
+// the inverter/MPPT models) to a raw register value.
+func scaled(value uint16, sf int16) float64 {
+	return float64(int16(value)) * math.Pow10(int(sf))
+}
+
+// scaledAcc32 applies a SunSpec scale factor to a 32-bit accumulator, such
+// as the inverter model's lifetime WH counter, which is stored as two
+// consecutive registers (high word first) rather than a single int16.
+func scaledAcc32(hi, lo uint16, sf int16) float64 {
+	return float64(uint32(hi)<<16|uint32(lo)) * math.Pow10(int(sf))
+}
+
+func registerString(regs []uint16) string {
+	b := make([]byte, len(regs)*2)
+	for i, r := range regs {
+		b[i*2] = byte(r >> 8)
+		b[i*2+1] = byte(r)
+	}
+	return strings.TrimRight(string(b), "\x00")
+}
+
+// GetInverterInfo implements Backend. SunSpec does not have the concept of
+// multiple logical inverters behind one Modbus endpoint, so a single
+// device is reported using its SunSpec common model identity.
+func (m *Modbus) GetInverterInfo(ctx context.Context) ([]*InverterInfo, error) {
+	addr, _, err := m.findModel(ctx, sunspecModelCommon)
+	if err != nil {
+		return nil, err
+	}
+
+	// Common model (1): Mn(16) Md(16) Opt(8) Vr(8) SN(16) DA(1)
+	regs, err := m.readRegisters(ctx, addr, 16+16+8+8+16)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read sunspec common model: %w", err)
+	}
+
+	model := registerString(regs[16:32])
+	serial := registerString(regs[48:64])
+
+	return []*InverterInfo{
+		{
+			Name:       "1",
+			CustomName: model,
+			UniqueID:   serial,
+			StatusCode: 7, // Running; Modbus has no concept of the Solar API's startup sub-states.
+		},
+	}, nil
+}
+
+// inverterModelAddr returns the data address and model ID of whichever
+// SunSpec inverter model (single, split or three phase) is present.
+func (m *Modbus) inverterModelAddr(ctx context.Context) (uint16, uint16, error) {
+	for _, id := range []uint16{sunspecModelInverterThree, sunspecModelInverterSplit, sunspecModelInverterSingle} {
+		if addr, length, err := m.findModel(ctx, id); err == nil {
+			return addr, length, nil
+		}
+	}
+	return 0, 0, fmt.Errorf("no sunspec inverter model found on device")
+}
+
+// GetInverterRealtimeCommonData implements Backend against SunSpec models
+// 101/102/103, which share a common register layout for AC/DC power,
+// frequency and lifetime energy.
+func (m *Modbus) GetInverterRealtimeCommonData(ctx context.Context, deviceID string) (*InverterCommonData, error) {
+	addr, _, err := m.inverterModelAddr(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Inverter model: A, AphA, AphB, AphC, A_SF, PPVphAB, PPVphBC, PPVphCA,
+	// PhVphA, PhVphB, PhVphC, V_SF, W, W_SF, Hz, Hz_SF, VA, VA_SF, VAr,
+	// VAr_SF, PF, PF_SF, WH(acc32), WH_SF, DCA, DCA_SF, DCV, DCV_SF, ...
+	regs, err := m.readRegisters(ctx, addr, 31)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read sunspec inverter model: %w", err)
+	}
+
+	aSF := int16(regs[4])
+	pacSF := int16(regs[13])
+	hzSF := int16(regs[15])
+	totalWhSF := int16(regs[24])
+	dcASF := int16(regs[26])
+	dcVSF := int16(regs[28])
+
+	return &InverterCommonData{
+		Fac:         DataValue{Unit: "Hz", Value: scaled(regs[14], hzSF)},
+		Iac:         DataValue{Unit: "A", Value: scaled(regs[0], aSF)},
+		Idc:         DataValue{Unit: "A", Value: scaled(regs[25], dcASF)},
+		Pac:         DataValue{Unit: "W", Value: scaled(regs[12], pacSF)},
+		Udc:         DataValue{Unit: "V", Value: scaled(regs[27], dcVSF)},
+		TotalEnergy: DataValue{Unit: "Wh", Value: scaledAcc32(regs[22], regs[23], totalWhSF)},
+	}, nil
+}
+
+// GetInverterRealtimeThreePhaseData implements Backend against SunSpec model
+// 103 (three phase inverter). Devices without model 103 (reporting only
+// model 101/102) return zero values for all three phases.
+func (m *Modbus) GetInverterRealtimeThreePhaseData(ctx context.Context, deviceID string) (*InverterThreePhaseData, error) {
+	addr, _, err := m.findModel(ctx, sunspecModelInverterThree)
+	if err != nil {
+		// Not every inverter is three phase; report zero values rather
+		// than erroring out the whole scrape.
+		return &InverterThreePhaseData{}, nil //nolint:nilerr
+	}
+
+	regs, err := m.readRegisters(ctx, addr, 12)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read sunspec three phase model: %w", err)
+	}
+
+	aSF := int16(regs[4])
+	vSF := int16(regs[11])
+
+	return &InverterThreePhaseData{
+		IacL1: DataValue{Unit: "A", Value: scaled(regs[1], aSF)},
+		IacL2: DataValue{Unit: "A", Value: scaled(regs[2], aSF)},
+		IacL3: DataValue{Unit: "A", Value: scaled(regs[3], aSF)},
+		UacL1: DataValue{Unit: "V", Value: scaled(regs[8], vSF)},
+		UacL2: DataValue{Unit: "V", Value: scaled(regs[9], vSF)},
+		UacL3: DataValue{Unit: "V", Value: scaled(regs[10], vSF)},
+	}, nil
+}
+
+// DCString is one MPPT input reported by the SunSpec multi-MPPT extension
+// model (160), such as a single string of panels.
+type DCString struct {
+	Name    string
+	Current DataValue
+	Voltage DataValue
+	Power   DataValue
+}
+
+// GetInverterDCStrings reads the per-MPPT DC inputs from SunSpec model 160,
+// where supported. It is not part of the Backend interface since the Solar
+// API has no equivalent; callers should type-assert for it.
+func (m *Modbus) GetInverterDCStrings(ctx context.Context, deviceID string) ([]DCString, error) {
+	addr, _, err := m.findModel(ctx, sunspecModelMultiMPPT)
+	if err != nil {
+		return nil, nil
+	}
+
+	// Multi MPPT model header: DCA_SF, DCV_SF, DCW_SF, DCWH_SF, Evt(2),
+	// N (module count), TmsPer.
+	header, err := m.readRegisters(ctx, addr, 8)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read sunspec multi-mppt header: %w", err)
+	}
+	aSF := int16(header[0])
+	vSF := int16(header[1])
+	wSF := int16(header[2])
+	count := header[6]
+
+	const moduleLength = 20
+	dcStrings := make([]DCString, 0, count)
+	for i := uint16(0); i < count; i++ {
+		moduleAddr := addr + 8 + i*moduleLength
+		regs, err := m.readRegisters(ctx, moduleAddr, moduleLength)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read sunspec mppt module %d: %w", i, err)
+		}
+
+		// MPPT module: ID, IDStr(8), DCA, DCV, DCW, DCWH(2), Tms(2), Tmp,
+		// DCSt, DCEvt(2).
+		dcStrings = append(dcStrings, DCString{
+			Name:    registerString(regs[1:9]),
+			Current: DataValue{Unit: "A", Value: scaled(regs[9], aSF)},
+			Voltage: DataValue{Unit: "V", Value: scaled(regs[10], vSF)},
+			Power:   DataValue{Unit: "W", Value: scaled(regs[11], wSF)},
+		})
+	}
+
+	return dcStrings, nil
+}
@@ -0,0 +1,71 @@
+// Package config parses the YAML file passed via --config.file, defining
+// the named modules selectable through the /probe?module= parameter of the
+// multi-target exporter.
+package config
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// BasicAuth holds optional HTTP basic auth credentials sent with every
+// Solar API request a module makes to its target; the Modbus backend does
+// not use them.
+type BasicAuth struct {
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+}
+
+// Module is one named configuration selectable via /probe?module=<name>.
+// The target itself is not part of the module; it comes from the
+// /probe?target= query parameter, so one module can be reused across a
+// whole fleet of inverters.
+type Module struct {
+	// Timeout bounds how long a single probe against this module's target
+	// may take. Defaults to the exporter's built-in collector timeout.
+	Timeout time.Duration `yaml:"timeout"`
+	// Backend selects how the target is talked to: "solarapi" (default) or
+	// "modbus".
+	Backend string `yaml:"backend"`
+	// BasicAuth, if set, is sent with every Solar API request to the target.
+	BasicAuth *BasicAuth `yaml:"basic_auth"`
+	// Collections restricts which data collections are scraped for this
+	// module: any of "inverter", "powerflow", "meter", "storage". An empty
+	// list enables all of them.
+	Collections []string `yaml:"collections"`
+}
+
+// Config is the top level structure of the --config.file YAML document.
+type Config struct {
+	Modules map[string]Module `yaml:"modules"`
+}
+
+// Load reads and parses a config file.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read config file: %w", err)
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("unable to parse config file: %w", err)
+	}
+	return &cfg, nil
+}
+
+// Module looks up a named module. An empty name resolves to the zero-value
+// default module (Solar API backend, no auth, all collections enabled).
+func (c *Config) Module(name string) (Module, error) {
+	if name == "" {
+		return Module{}, nil
+	}
+	m, ok := c.Modules[name]
+	if !ok {
+		return Module{}, fmt.Errorf("unknown module %q", name)
+	}
+	return m, nil
+}
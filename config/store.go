@@ -0,0 +1,48 @@
+package config
+
+import "sync"
+
+// Store holds the currently active Config and supports safe concurrent
+// hot-reloads, as used for the --config.file SIGHUP/-/reload workflow.
+type Store struct {
+	mu   sync.RWMutex
+	path string
+	cfg  *Config
+}
+
+// NewStore loads path, if set, and returns a Store ready to be read and
+// reloaded. An empty path yields a Store serving the zero-value Config.
+func NewStore(path string) (*Store, error) {
+	s := &Store{path: path, cfg: &Config{}}
+	if path != "" {
+		if err := s.Reload(); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Get returns the currently active Config.
+func (s *Store) Get() *Config {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.cfg
+}
+
+// Reload reparses the config file and swaps it in on success. On failure
+// the previously loaded Config is left untouched and keeps serving.
+func (s *Store) Reload() error {
+	if s.path == "" {
+		return nil
+	}
+
+	cfg, err := Load(s.path)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.cfg = cfg
+	s.mu.Unlock()
+	return nil
+}
@@ -6,9 +6,13 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"os/signal"
+	"path/filepath"
 	"strings"
+	"syscall"
 	"time"
 
+	"github.com/fsnotify/fsnotify"
 	"github.com/justinas/alice"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/collectors"
@@ -17,6 +21,8 @@ import (
 	"github.com/rs/zerolog/hlog"
 
 	"github.com/simonswine/fronius-exporter/api"
+	"github.com/simonswine/fronius-exporter/config"
+	"github.com/simonswine/fronius-exporter/remotewrite"
 )
 
 const timeout = 15 * time.Second
@@ -26,21 +32,96 @@ var log = zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr, TimeFormat: time.RFC
 	Logger()
 
 type collector struct {
-	api *api.Fronius
-
-	inverterInfo        *prometheus.Desc
-	inverterStatus      *prometheus.Desc
-	inverterTotalEnergy *prometheus.Desc
-	inverterDCVoltage   *prometheus.Desc
-	inverterDCCurrent   *prometheus.Desc
-	inverterACFrequency *prometheus.Desc
-	inverterACVoltage   *prometheus.Desc
-	inverterACCurrent   *prometheus.Desc
+	api api.Backend
+	// timeout bounds each individual data collection request made during a
+	// single Collect call.
+	timeout time.Duration
+	// collections restricts which data collections are scraped; a nil map
+	// enables all of them.
+	collections map[string]bool
+
+	inverterInfo            *prometheus.Desc
+	inverterStatus          *prometheus.Desc
+	inverterErrorCode       *prometheus.Desc
+	inverterLED             *prometheus.Desc
+	inverterMgmtTimer       *prometheus.Desc
+	inverterTotalEnergy     *prometheus.Desc
+	inverterDCVoltage       *prometheus.Desc
+	inverterDCCurrent       *prometheus.Desc
+	inverterACFrequency     *prometheus.Desc
+	inverterACVoltage       *prometheus.Desc
+	inverterACCurrent       *prometheus.Desc
+	inverterDCStringCurrent *prometheus.Desc
+	inverterDCStringVoltage *prometheus.Desc
+	inverterDCStringPower   *prometheus.Desc
+
+	sitePowerGrid            *prometheus.Desc
+	sitePowerLoad            *prometheus.Desc
+	sitePowerPV              *prometheus.Desc
+	sitePowerBattery         *prometheus.Desc
+	siteEnergyDay            *prometheus.Desc
+	siteEnergyYear           *prometheus.Desc
+	siteEnergyTotal          *prometheus.Desc
+	siteAutonomyRatio        *prometheus.Desc
+	siteSelfConsumptionRatio *prometheus.Desc
+
+	meterEnergyImported *prometheus.Desc
+	meterEnergyExported *prometheus.Desc
+	meterVoltage        *prometheus.Desc
+	meterCurrent        *prometheus.Desc
+	meterPowerFactor    *prometheus.Desc
+
+	storageStateOfCharge    *prometheus.Desc
+	storageVoltage          *prometheus.Desc
+	storageCurrent          *prometheus.Desc
+	storageTemperature      *prometheus.Desc
+	storagePower            *prometheus.Desc
+	storageDesignedCapacity *prometheus.Desc
+	storageCapacityMaximum  *prometheus.Desc
+	storageCycleCount       *prometheus.Desc
+	storageStatus           *prometheus.Desc
+}
+
+// storageProvider is implemented by backends that expose battery/storage
+// data; the Solar API supports it, Modbus currently does not.
+type storageProvider interface {
+	GetStorageRealtimeData(ctx context.Context) (map[string]*api.StorageRealtimeData, error)
+}
+
+// powerFlowProvider is implemented by backends that can report the combined
+// household power flow; the Solar API supports it, Modbus currently does not.
+type powerFlowProvider interface {
+	GetPowerFlowRealtimeData(ctx context.Context) (*api.PowerFlowRealtimeData, error)
+}
+
+// meterProvider is implemented by backends that expose smart meter data.
+type meterProvider interface {
+	GetMeterRealtimeData(ctx context.Context) (map[string]*api.MeterRealtimeData, error)
 }
 
-func newCollector(api *api.Fronius) *collector {
+// dcStringsProvider is implemented by backends that expose per-MPPT DC
+// string data; Modbus supports it via SunSpec model 160, the Solar API does
+// not.
+type dcStringsProvider interface {
+	GetInverterDCStrings(ctx context.Context, deviceID string) ([]api.DCString, error)
+}
+
+// newCollectorForModule builds a collector honouring a module's timeout and
+// enabled collections, as configured via --config.file. A nil or empty
+// enabledCollections enables all of them.
+func newCollectorForModule(api api.Backend, collectTimeout time.Duration, enabledCollections []string) *collector {
+	var collections map[string]bool
+	if len(enabledCollections) > 0 {
+		collections = make(map[string]bool, len(enabledCollections))
+		for _, name := range enabledCollections {
+			collections[name] = true
+		}
+	}
+
 	return &collector{
-		api: api,
+		api:         api,
+		timeout:     collectTimeout,
+		collections: collections,
 		inverterInfo: prometheus.NewDesc(
 			"fronius_inverter_info",
 			"Information about the inverter",
@@ -53,6 +134,24 @@ func newCollector(api *api.Fronius) *collector {
 			[]string{"device_id", "status"},
 			nil,
 		),
+		inverterErrorCode: prometheus.NewDesc(
+			"fronius_inverter_error_code",
+			"Error code currently present on the inverter, 0 means no error",
+			[]string{"device_id"},
+			nil,
+		),
+		inverterLED: prometheus.NewDesc(
+			"fronius_inverter_led",
+			"Status LED currently shown on the inverter, see the Solar API spec for color/state codes",
+			[]string{"device_id", "color", "state"},
+			nil,
+		),
+		inverterMgmtTimer: prometheus.NewDesc(
+			"fronius_inverter_mgmt_timer_seconds",
+			"Remaining time of the inverter's management timer",
+			[]string{"device_id"},
+			nil,
+		),
 		inverterTotalEnergy: prometheus.NewDesc(
 			"inverter_yield_total",
 			"Information about the inverter",
@@ -89,6 +188,162 @@ func newCollector(api *api.Fronius) *collector {
 			[]string{"device_id", "phase"},
 			nil,
 		),
+		inverterDCStringCurrent: prometheus.NewDesc(
+			"fronius_inverter_dc_string_current_amperes",
+			"Per-MPPT DC string current",
+			[]string{"device_id", "string"},
+			nil,
+		),
+		inverterDCStringVoltage: prometheus.NewDesc(
+			"fronius_inverter_dc_string_voltage_volts",
+			"Per-MPPT DC string voltage",
+			[]string{"device_id", "string"},
+			nil,
+		),
+		inverterDCStringPower: prometheus.NewDesc(
+			"fronius_inverter_dc_string_power_watts",
+			"Per-MPPT DC string power",
+			[]string{"device_id", "string"},
+			nil,
+		),
+		sitePowerGrid: prometheus.NewDesc(
+			"fronius_site_power_grid_watts",
+			"Power exchanged with the grid, positive means import",
+			nil,
+			nil,
+		),
+		sitePowerLoad: prometheus.NewDesc(
+			"fronius_site_power_load_watts",
+			"Household load power",
+			nil,
+			nil,
+		),
+		sitePowerPV: prometheus.NewDesc(
+			"fronius_site_power_pv_watts",
+			"PV generation power",
+			nil,
+			nil,
+		),
+		sitePowerBattery: prometheus.NewDesc(
+			"fronius_site_power_battery_watts",
+			"Battery power, positive means discharging",
+			nil,
+			nil,
+		),
+		siteEnergyDay: prometheus.NewDesc(
+			"fronius_site_energy_day_wh",
+			"Energy generated today",
+			nil,
+			nil,
+		),
+		siteEnergyYear: prometheus.NewDesc(
+			"fronius_site_energy_year_wh",
+			"Energy generated this year",
+			nil,
+			nil,
+		),
+		siteEnergyTotal: prometheus.NewDesc(
+			"fronius_site_energy_total_wh",
+			"Energy generated since commissioning",
+			nil,
+			nil,
+		),
+		siteAutonomyRatio: prometheus.NewDesc(
+			"fronius_site_autonomy_ratio",
+			"Share of household load covered without grid import",
+			nil,
+			nil,
+		),
+		siteSelfConsumptionRatio: prometheus.NewDesc(
+			"fronius_site_self_consumption_ratio",
+			"Share of PV generation consumed on site",
+			nil,
+			nil,
+		),
+		meterEnergyImported: prometheus.NewDesc(
+			"fronius_meter_energy_imported_wh",
+			"Energy imported from the grid as measured by this meter",
+			[]string{"meter_id", "manufacturer", "model", "serial"},
+			nil,
+		),
+		meterEnergyExported: prometheus.NewDesc(
+			"fronius_meter_energy_exported_wh",
+			"Energy exported to the grid as measured by this meter",
+			[]string{"meter_id", "manufacturer", "model", "serial"},
+			nil,
+		),
+		meterVoltage: prometheus.NewDesc(
+			"fronius_meter_voltage_volts",
+			"AC voltage as measured by this meter",
+			[]string{"meter_id", "phase"},
+			nil,
+		),
+		meterCurrent: prometheus.NewDesc(
+			"fronius_meter_current_amperes",
+			"AC current as measured by this meter",
+			[]string{"meter_id", "phase"},
+			nil,
+		),
+		meterPowerFactor: prometheus.NewDesc(
+			"fronius_meter_power_factor",
+			"AC power factor as measured by this meter",
+			[]string{"meter_id", "phase"},
+			nil,
+		),
+		storageStateOfCharge: prometheus.NewDesc(
+			"fronius_storage_state_of_charge_ratio",
+			"Relative state of charge",
+			[]string{"storage_id", "unit"},
+			nil,
+		),
+		storageVoltage: prometheus.NewDesc(
+			"fronius_storage_voltage_volts",
+			"Battery DC voltage",
+			[]string{"storage_id", "unit"},
+			nil,
+		),
+		storageCurrent: prometheus.NewDesc(
+			"fronius_storage_current_amperes",
+			"Battery DC current",
+			[]string{"storage_id", "unit"},
+			nil,
+		),
+		storageTemperature: prometheus.NewDesc(
+			"fronius_storage_temperature_celsius",
+			"Battery cell temperature",
+			[]string{"storage_id", "unit"},
+			nil,
+		),
+		storagePower: prometheus.NewDesc(
+			"fronius_storage_power_watts",
+			"Battery charge/discharge power, positive means discharging",
+			[]string{"storage_id", "unit"},
+			nil,
+		),
+		storageDesignedCapacity: prometheus.NewDesc(
+			"fronius_storage_designed_capacity_wh",
+			"Designed battery capacity",
+			[]string{"storage_id", "unit"},
+			nil,
+		),
+		storageCapacityMaximum: prometheus.NewDesc(
+			"fronius_storage_capacity_maximum_wh",
+			"Usable battery capacity",
+			[]string{"storage_id", "unit"},
+			nil,
+		),
+		storageCycleCount: prometheus.NewDesc(
+			"fronius_storage_cycle_count",
+			"Battery charge cycle count",
+			[]string{"storage_id", "unit"},
+			nil,
+		),
+		storageStatus: prometheus.NewDesc(
+			"fronius_storage_status_code",
+			"Raw battery cell status code, see the Solar API spec",
+			[]string{"storage_id", "unit"},
+			nil,
+		),
 	}
 }
 
@@ -96,17 +351,46 @@ func newCollector(api *api.Fronius) *collector {
 func (c *collector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- c.inverterInfo
 	ch <- c.inverterStatus
+	ch <- c.inverterErrorCode
+	ch <- c.inverterLED
+	ch <- c.inverterMgmtTimer
 	ch <- c.inverterTotalEnergy
 	ch <- c.inverterDCVoltage
 	ch <- c.inverterDCCurrent
 	ch <- c.inverterACFrequency
 	ch <- c.inverterACVoltage
 	ch <- c.inverterACCurrent
+	ch <- c.inverterDCStringCurrent
+	ch <- c.inverterDCStringVoltage
+	ch <- c.inverterDCStringPower
+	ch <- c.sitePowerGrid
+	ch <- c.sitePowerLoad
+	ch <- c.sitePowerPV
+	ch <- c.sitePowerBattery
+	ch <- c.siteEnergyDay
+	ch <- c.siteEnergyYear
+	ch <- c.siteEnergyTotal
+	ch <- c.siteAutonomyRatio
+	ch <- c.siteSelfConsumptionRatio
+	ch <- c.meterEnergyImported
+	ch <- c.meterEnergyExported
+	ch <- c.meterVoltage
+	ch <- c.meterCurrent
+	ch <- c.meterPowerFactor
+	ch <- c.storageStateOfCharge
+	ch <- c.storageVoltage
+	ch <- c.storageCurrent
+	ch <- c.storageTemperature
+	ch <- c.storagePower
+	ch <- c.storageDesignedCapacity
+	ch <- c.storageCapacityMaximum
+	ch <- c.storageCycleCount
+	ch <- c.storageStatus
 }
 
 // Collect implements Collector.
 func (c *collector) collectInverters(ch chan<- prometheus.Metric) (deviceIDs []string) {
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
 	defer cancel()
 
 	inverters, err := c.api.GetInverterInfo(ctx)
@@ -154,13 +438,25 @@ func (c *collector) collectInverters(ch chan<- prometheus.Metric) (deviceIDs []s
 			ch <- m
 		}
 
+		// report error code of inverter
+		m, err = prometheus.NewConstMetric(
+			c.inverterErrorCode,
+			prometheus.GaugeValue,
+			float64(inverter.ErrorCode),
+			inverter.Name,
+		)
+		if err != nil {
+			log.Err(err).Msg("unable to generate metrics for inverter error code")
+			continue
+		}
+		ch <- m
 	}
 
 	return ids
 }
 
 func (c *collector) collectCommonInverterData(ch chan<- prometheus.Metric, id string) {
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
 	defer cancel()
 
 	data, err := c.api.GetInverterRealtimeCommonData(ctx, id)
@@ -168,6 +464,34 @@ func (c *collector) collectCommonInverterData(ch chan<- prometheus.Metric, id st
 		log.Err(err).Msg("unable to get common data for inverter")
 		return
 	}
+	{
+		m, err := prometheus.NewConstMetric(
+			c.inverterLED,
+			prometheus.GaugeValue,
+			1.0,
+			id,
+			fmt.Sprintf("%d", data.DeviceStatus.LEDColor),
+			fmt.Sprintf("%d", data.DeviceStatus.LEDState),
+		)
+		if err != nil {
+			log.Err(err).Msg("unable to generate metrics for inverter led")
+			return
+		}
+		ch <- m
+	}
+	{
+		m, err := prometheus.NewConstMetric(
+			c.inverterMgmtTimer,
+			prometheus.GaugeValue,
+			float64(data.DeviceStatus.MgmtTimerRemainingTime),
+			id,
+		)
+		if err != nil {
+			log.Err(err).Msg("unable to generate metrics for inverter mgmt timer")
+			return
+		}
+		ch <- m
+	}
 	{
 		m, err := prometheus.NewConstMetric(
 			c.inverterTotalEnergy,
@@ -223,7 +547,7 @@ func (c *collector) collectCommonInverterData(ch chan<- prometheus.Metric, id st
 }
 
 func (c *collector) collectThreePhaseInverterData(ch chan<- prometheus.Metric, id string) {
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
 	defer cancel()
 
 	data, err := c.api.GetInverterRealtimeThreePhaseData(ctx, id)
@@ -261,43 +585,409 @@ func (c *collector) collectThreePhaseInverterData(ch chan<- prometheus.Metric, i
 
 }
 
+func (c *collector) collectDCStrings(ch chan<- prometheus.Metric, id string) {
+	provider, ok := c.api.(dcStringsProvider)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	dcStrings, err := provider.GetInverterDCStrings(ctx, id)
+	if err != nil {
+		log.Err(err).Msg("unable to get dc strings for inverter")
+		return
+	}
+
+	for _, s := range dcStrings {
+		for _, x := range []struct {
+			desc  *prometheus.Desc
+			value float64
+		}{
+			{c.inverterDCStringCurrent, s.Current.Value},
+			{c.inverterDCStringVoltage, s.Voltage.Value},
+			{c.inverterDCStringPower, s.Power.Value},
+		} {
+			m, err := prometheus.NewConstMetric(x.desc, prometheus.GaugeValue, x.value, id, s.Name)
+			if err != nil {
+				log.Err(err).Msg("unable to generate metrics for dc string")
+				continue
+			}
+			ch <- m
+		}
+	}
+}
+
+func (c *collector) collectPowerFlow(ch chan<- prometheus.Metric) {
+	provider, ok := c.api.(powerFlowProvider)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	data, err := provider.GetPowerFlowRealtimeData(ctx)
+	if err != nil {
+		log.Err(err).Msg("unable to get power flow data")
+		return
+	}
+
+	for _, x := range []struct {
+		desc  *prometheus.Desc
+		value float64
+	}{
+		{c.sitePowerGrid, data.Site.PGrid},
+		{c.sitePowerLoad, data.Site.PLoad},
+		{c.sitePowerPV, data.Site.PPV},
+		{c.sitePowerBattery, data.Site.PAkku},
+		{c.siteEnergyDay, data.Site.EDay},
+		{c.siteEnergyYear, data.Site.EYear},
+		{c.siteEnergyTotal, data.Site.ETotal},
+		{c.siteAutonomyRatio, data.Site.RelAutonomy / 100.0},
+		{c.siteSelfConsumptionRatio, data.Site.RelSelfConsumption / 100.0},
+	} {
+		m, err := prometheus.NewConstMetric(x.desc, prometheus.GaugeValue, x.value)
+		if err != nil {
+			log.Err(err).Msg("unable to generate metrics for power flow")
+			continue
+		}
+		ch <- m
+	}
+}
+
+func (c *collector) collectMeters(ch chan<- prometheus.Metric) {
+	provider, ok := c.api.(meterProvider)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	meters, err := provider.GetMeterRealtimeData(ctx)
+	if err != nil {
+		log.Err(err).Msg("unable to get meter data")
+		return
+	}
+
+	for id, meter := range meters {
+		for _, x := range []struct {
+			desc  *prometheus.Desc
+			value float64
+		}{
+			{c.meterEnergyImported, meter.EnergyRealWACPlusAbsolute},
+			{c.meterEnergyExported, meter.EnergyRealWACMinusAbsolute},
+		} {
+			m, err := prometheus.NewConstMetric(
+				x.desc,
+				prometheus.CounterValue,
+				x.value,
+				id,
+				meter.Details.Manufacturer,
+				meter.Details.Model,
+				meter.Details.Serial,
+			)
+			if err != nil {
+				log.Err(err).Msg("unable to generate metrics for meter energy")
+				continue
+			}
+			ch <- m
+		}
+
+		for _, x := range []struct {
+			desc  *prometheus.Desc
+			value float64
+			phase string
+		}{
+			{c.meterVoltage, meter.VoltageACPhase1, "L1"},
+			{c.meterVoltage, meter.VoltageACPhase2, "L2"},
+			{c.meterVoltage, meter.VoltageACPhase3, "L3"},
+			{c.meterCurrent, meter.CurrentACPhase1, "L1"},
+			{c.meterCurrent, meter.CurrentACPhase2, "L2"},
+			{c.meterCurrent, meter.CurrentACPhase3, "L3"},
+			{c.meterPowerFactor, meter.PowerFactorPhase1, "L1"},
+			{c.meterPowerFactor, meter.PowerFactorPhase2, "L2"},
+			{c.meterPowerFactor, meter.PowerFactorPhase3, "L3"},
+		} {
+			m, err := prometheus.NewConstMetric(x.desc, prometheus.GaugeValue, x.value, id, x.phase)
+			if err != nil {
+				log.Err(err).Msg("unable to generate metrics for meter phase data")
+				continue
+			}
+			ch <- m
+		}
+	}
+}
+
+func (c *collector) collectStorageUnit(ch chan<- prometheus.Metric, storageID, unit string, soc, voltage, current, temperature, designedCapacity, capacityMaximum float64, cycleCount, status int) {
+	for _, x := range []struct {
+		desc  *prometheus.Desc
+		value float64
+	}{
+		{c.storageStateOfCharge, soc / 100.0},
+		{c.storageVoltage, voltage},
+		{c.storageCurrent, current},
+		{c.storageTemperature, temperature},
+		{c.storagePower, voltage * current},
+		{c.storageDesignedCapacity, designedCapacity},
+		{c.storageCapacityMaximum, capacityMaximum},
+		{c.storageCycleCount, float64(cycleCount)},
+		{c.storageStatus, float64(status)},
+	} {
+		m, err := prometheus.NewConstMetric(x.desc, prometheus.GaugeValue, x.value, storageID, unit)
+		if err != nil {
+			log.Err(err).Msg("unable to generate metrics for storage unit")
+			continue
+		}
+		ch <- m
+	}
+}
+
+func (c *collector) collectStorage(ch chan<- prometheus.Metric) {
+	provider, ok := c.api.(storageProvider)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	storages, err := provider.GetStorageRealtimeData(ctx)
+	if err != nil {
+		log.Err(err).Msg("unable to get storage data")
+		return
+	}
+
+	for id, storage := range storages {
+		ctrl := storage.Controller
+		c.collectStorageUnit(ch, id, "controller",
+			ctrl.StateOfChargeRelative, ctrl.VoltageDC, ctrl.CurrentDC, ctrl.TemperatureCell,
+			ctrl.DesignedCapacity, ctrl.CapacityMaximum, 0, ctrl.StatusBatteryCell)
+
+		for i, module := range storage.Modules {
+			c.collectStorageUnit(ch, id, fmt.Sprintf("module%d", i),
+				module.StateOfChargeRelative, module.VoltageDC, module.CurrentDC, module.TemperatureCell,
+				module.DesignedCapacity, 0, module.CycleCountBatteryCell, module.StatusBatteryCell)
+		}
+	}
+}
+
+// enabled reports whether the named data collection should be scraped.
+// A collector with no explicit collections configured scrapes everything.
+func (c *collector) enabled(name string) bool {
+	if c.collections == nil {
+		return true
+	}
+	return c.collections[name]
+}
+
 func (c *collector) Collect(ch chan<- prometheus.Metric) {
+	if c.enabled("inverter") {
+		ids := c.collectInverters(ch)
 
-	ids := c.collectInverters(ch)
+		for _, id := range ids {
+			c.collectCommonInverterData(ch, id)
+			c.collectThreePhaseInverterData(ch, id)
+			c.collectDCStrings(ch, id)
+		}
+	}
 
-	for _, id := range ids {
-		c.collectCommonInverterData(ch, id)
-		c.collectThreePhaseInverterData(ch, id)
+	if c.enabled("powerflow") {
+		c.collectPowerFlow(ch)
+	}
+	if c.enabled("meter") {
+		c.collectMeters(ch)
+	}
+	if c.enabled("storage") {
+		c.collectStorage(ch)
+	}
+}
+
+// closer is implemented by backends that hold a resource (e.g. the Modbus
+// backend's TCP connection) that must be released once a probe is done
+// with it.
+type closer interface {
+	Close() error
+}
+
+// newBackend constructs the api.Backend a module talks to its target
+// through, applying the module's selected backend and optional basic auth.
+// ctx bounds the connection attempt, e.g. Modbus's initial TCP dial.
+func newBackend(ctx context.Context, module config.Module, target string) (api.Backend, error) {
+	switch module.Backend {
+	case "modbus":
+		return api.NewModbus(ctx, target, 1)
+	case "solarapi", "":
+		f, err := api.NewFronius(target)
+		if err != nil {
+			return nil, err
+		}
+		if module.BasicAuth != nil {
+			f.Client = &http.Client{
+				Transport: basicAuthTransport{
+					username:  module.BasicAuth.Username,
+					password:  module.BasicAuth.Password,
+					transport: http.DefaultTransport,
+				},
+			}
+		}
+		return f, nil
+	default:
+		return nil, fmt.Errorf("unknown backend %q", module.Backend)
 	}
 }
 
+type basicAuthTransport struct {
+	username, password string
+	transport          http.RoundTripper
+}
+
+func (t basicAuthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req.SetBasicAuth(t.username, t.password)
+	return t.transport.RoundTrip(req)
+}
+
+// probeHandler implements the "multi-target exporter" /probe endpoint:
+// it builds a backend for the requested target/module pair, runs a single
+// collection through a fresh registry, and serves the result.
+func probeHandler(store *config.Store, probeDuration *prometheus.HistogramVec, probeFailures *prometheus.CounterVec) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		target := r.URL.Query().Get("target")
+		if target == "" {
+			http.Error(w, "target parameter is required", http.StatusBadRequest)
+			return
+		}
+		moduleName := r.URL.Query().Get("module")
+
+		module, err := store.Get().Module(moduleName)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		probeTimeout := module.Timeout
+		if probeTimeout == 0 {
+			probeTimeout = timeout
+		}
+
+		start := time.Now()
+
+		ctx, cancel := context.WithTimeout(r.Context(), probeTimeout)
+		defer cancel()
+
+		a, err := newBackend(ctx, module, target)
+		if err != nil {
+			probeFailures.WithLabelValues(target, moduleName).Inc()
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		if c, ok := a.(closer); ok {
+			defer c.Close()
+		}
+
+		reg := prometheus.NewRegistry()
+		if err := reg.Register(newCollectorForModule(a, probeTimeout, module.Collections)); err != nil {
+			probeFailures.WithLabelValues(target, moduleName).Inc()
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		promhttp.HandlerFor(reg, promhttp.HandlerOpts{EnableOpenMetrics: true}).ServeHTTP(w, r)
+		probeDuration.WithLabelValues(target, moduleName).Observe(time.Since(start).Seconds())
+	}
+}
+
+// reloadHandler reparses the config file on every POST, reporting the
+// outcome through configLastReloadSuccessful/Timestamp, as exposed via
+// SIGHUP and /-/reload.
+func reloadHandler(store *config.Store, successful, successTimestamp prometheus.Gauge) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "only POST is allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		reloadConfig(store, successful, successTimestamp)
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// reloadConfig reloads store, keeping the previous good config on failure
+// and recording the outcome in the reload gauges.
+func reloadConfig(store *config.Store, successful, successTimestamp prometheus.Gauge) {
+	if err := store.Reload(); err != nil {
+		log.Err(err).Msg("unable to reload config, keeping previous config")
+		successful.Set(0)
+		return
+	}
+	successful.Set(1)
+	successTimestamp.SetToCurrentTime()
+}
+
 func run() error {
 
 	var (
-		addr string
-		url  string
+		addr                      string
+		configFile                string
+		remoteWriteURL            string
+		remoteWriteProtocol       string
+		remoteWriteInterval       time.Duration
+		remoteWriteBatchSize      int
+		remoteWriteExternalLabels string
+		remoteWriteTarget         string
+		remoteWriteModule         string
 	)
 	flag.StringVar(&addr, "listen-address", ":9109", "The address to listen on for HTTP requests.")
-	flag.StringVar(&url, "fronius-url", "", "URL for the fronius inverter.")
+	flag.StringVar(&configFile, "config.file", "", "Path to a YAML file defining named modules for /probe?target=<host>&module=<name>.")
+	flag.StringVar(&remoteWriteURL, "remote-write.url", "", "If set, periodically push samples to this Prometheus remote-write or OTLP endpoint.")
+	flag.StringVar(&remoteWriteProtocol, "remote-write.protocol", string(remotewrite.ProtocolPrometheus), "Wire format used for --remote-write.url, either \"prometheus\" or \"otlp\".")
+	flag.DurationVar(&remoteWriteInterval, "remote-write.interval", 60*time.Second, "How often to scrape the remote-write target for samples.")
+	flag.IntVar(&remoteWriteBatchSize, "remote-write.batch-size", 500, "Maximum number of samples buffered before a remote-write flush is forced.")
+	flag.StringVar(&remoteWriteExternalLabels, "remote-write.external-labels", "", "Comma separated list of name=value labels attached to every remote-written sample.")
+	flag.StringVar(&remoteWriteTarget, "remote-write.target", "", "Inverter target to scrape and push via remote-write.")
+	flag.StringVar(&remoteWriteModule, "remote-write.module", "", "Named module (from --config.file) used for the remote-write target.")
 	flag.Parse()
 
-	if url == "" {
-		return fmt.Errorf("no fronius-url set")
-	}
-
-	// create fronius collector
-	a, err := api.NewFronius(url)
+	store, err := config.NewStore(configFile)
 	if err != nil {
 		return err
 	}
 
-	coll := newCollector(a)
+	probeDuration := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "fronius_exporter_probe_duration_seconds",
+		Help: "Duration of a /probe scrape of an inverter target.",
+	}, []string{"target", "module"})
+	probeFailures := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "fronius_exporter_probe_failures_total",
+		Help: "Number of failed /probe scrapes of an inverter target.",
+	}, []string{"target", "module"})
+	configLastReloadSuccessful := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "fronius_exporter_config_last_reload_successful",
+		Help: "Whether the last config file reload succeeded.",
+	})
+	configLastReloadSuccessTimestamp := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "fronius_exporter_config_last_reload_success_timestamp_seconds",
+		Help: "Timestamp of the last successful config file reload.",
+	})
+	// The initial load above already succeeded, or there was no config
+	// file to load; either way that counts as a successful "reload".
+	configLastReloadSuccessful.Set(1)
+	configLastReloadSuccessTimestamp.SetToCurrentTime()
 
 	reg := prometheus.NewRegistry()
-	if err := reg.Register(coll); err != nil {
+	if err := reg.Register(probeDuration); err != nil {
+		return err
+	}
+	if err := reg.Register(probeFailures); err != nil {
+		return err
+	}
+	if err := reg.Register(configLastReloadSuccessful); err != nil {
+		return err
+	}
+	if err := reg.Register(configLastReloadSuccessTimestamp); err != nil {
 		return err
 	}
-
 	// go module build info.
 	if err := reg.Register(collectors.NewBuildInfoCollector()); err != nil {
 		return err
@@ -306,12 +996,111 @@ func run() error {
 		return err
 	}
 
+	if configFile != "" {
+		// Watch the parent directory rather than configFile itself: a
+		// ConfigMap mount swaps the file in by replacing a symlink, which
+		// fsnotify reports as Remove/Rename of configFile rather than a
+		// Write, and re-adding a watch on a path that no longer exists
+		// fails. This mirrors viper's WatchConfig.
+		configDir := filepath.Dir(configFile)
+
+		watcher, err := fsnotify.NewWatcher()
+		if err != nil {
+			return fmt.Errorf("unable to watch config file: %w", err)
+		}
+		if err := watcher.Add(configDir); err != nil {
+			return fmt.Errorf("unable to watch config file: %w", err)
+		}
+		defer watcher.Close()
+
+		sighup := make(chan os.Signal, 1)
+		signal.Notify(sighup, syscall.SIGHUP)
+
+		go func() {
+			for {
+				select {
+				case event, ok := <-watcher.Events:
+					if !ok {
+						return
+					}
+					if filepath.Clean(event.Name) != configFile {
+						continue
+					}
+					if event.Op&(fsnotify.Remove|fsnotify.Rename) != 0 {
+						// The old inode is gone; re-add the directory watch
+						// so we keep seeing events once the replacement
+						// file lands.
+						if err := watcher.Add(configDir); err != nil {
+							log.Err(err).Msg("unable to re-add config file watch")
+						}
+					}
+					if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Remove|fsnotify.Rename) != 0 {
+						reloadConfig(store, configLastReloadSuccessful, configLastReloadSuccessTimestamp)
+					}
+				case err, ok := <-watcher.Errors:
+					if !ok {
+						return
+					}
+					log.Err(err).Msg("error watching config file")
+				case <-sighup:
+					reloadConfig(store, configLastReloadSuccessful, configLastReloadSuccessTimestamp)
+				}
+			}
+		}()
+	}
+
+	if remoteWriteURL != "" {
+		if remoteWriteTarget == "" {
+			return fmt.Errorf("--remote-write.target is required when --remote-write.url is set")
+		}
+
+		module, err := store.Get().Module(remoteWriteModule)
+		if err != nil {
+			return err
+		}
+
+		probeTimeout := module.Timeout
+		if probeTimeout == 0 {
+			probeTimeout = timeout
+		}
+
+		connectCtx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+		defer cancel()
+
+		a, err := newBackend(connectCtx, module, remoteWriteTarget)
+		if err != nil {
+			return err
+		}
+
+		rwReg := prometheus.NewRegistry()
+		if err := rwReg.Register(newCollectorForModule(a, probeTimeout, module.Collections)); err != nil {
+			return err
+		}
+
+		rwCfg := remotewrite.DefaultConfig()
+		rwCfg.URL = remoteWriteURL
+		rwCfg.Protocol = remotewrite.Protocol(remoteWriteProtocol)
+		rwCfg.Interval = remoteWriteInterval
+		rwCfg.BatchSize = remoteWriteBatchSize
+		rwCfg.ExternalLabels, err = parseExternalLabels(remoteWriteExternalLabels)
+		if err != nil {
+			return err
+		}
+
+		qm, err := remotewrite.NewQueueManager(rwCfg, log)
+		if err != nil {
+			return err
+		}
+		go qm.Run(context.Background(), rwReg)
+	}
+
 	// Install the logger handler with default output on the console
 	c := alice.New()
 	c = c.Append(hlog.NewHandler(log))
 
-	// Expose the registered metrics via HTTP.
 	mux := http.NewServeMux()
+	// /metrics carries exporter self-telemetry only; inverter metrics are
+	// served per-target via /probe.
 	mux.Handle("/metrics", promhttp.HandlerFor(
 		reg,
 		promhttp.HandlerOpts{
@@ -319,6 +1108,8 @@ func run() error {
 			EnableOpenMetrics: true,
 		},
 	))
+	mux.HandleFunc("/probe", probeHandler(store, probeDuration, probeFailures))
+	mux.HandleFunc("/-/reload", reloadHandler(store, configLastReloadSuccessful, configLastReloadSuccessTimestamp))
 
 	c = c.Append(hlog.AccessHandler(func(r *http.Request, status, size int, duration time.Duration) {
 		hlog.FromRequest(r).Info().
@@ -333,6 +1124,24 @@ func run() error {
 	return http.ListenAndServe(addr, c.Then(mux))
 }
 
+// parseExternalLabels parses a comma separated name=value list, as accepted
+// by the --remote-write.external-labels flag.
+func parseExternalLabels(s string) (map[string]string, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	labels := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid external label %q, expected name=value", pair)
+		}
+		labels[parts[0]] = parts[1]
+	}
+	return labels, nil
+}
+
 func main() {
 	if err := run(); err != nil {
 		log.Fatal().Err(err).Msg("failed")
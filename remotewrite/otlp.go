@@ -0,0 +1,69 @@
+package remotewrite
+
+import (
+	"github.com/gogo/protobuf/proto"
+	"go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricpb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+
+	"github.com/prometheus/prometheus/prompb"
+)
+
+// encodeOTLPMetrics converts remote-write style time series into an OTLP
+// ExportMetricsServiceRequest, so the same buffered samples can be shipped
+// to either wire format depending on Config.Protocol.
+func encodeOTLPMetrics(series []prompb.TimeSeries) ([]byte, error) {
+	req := &v1.ExportMetricsServiceRequest{
+		ResourceMetrics: []*metricpb.ResourceMetrics{
+			{
+				Resource: &resourcepb.Resource{},
+				ScopeMetrics: []*metricpb.ScopeMetrics{
+					{Metrics: make([]*metricpb.Metric, 0, len(series))},
+				},
+			},
+		},
+	}
+
+	scope := req.ResourceMetrics[0].ScopeMetrics[0]
+	for _, ts := range series {
+		name, attrs := splitNameAndLabels(ts.Labels)
+		if name == "" || len(ts.Samples) == 0 {
+			continue
+		}
+
+		points := make([]*metricpb.NumberDataPoint, 0, len(ts.Samples))
+		for _, s := range ts.Samples {
+			points = append(points, &metricpb.NumberDataPoint{
+				Attributes:   attrs,
+				TimeUnixNano: uint64(s.Timestamp) * 1e6,
+				Value:        &metricpb.NumberDataPoint_AsDouble{AsDouble: s.Value},
+			})
+		}
+
+		scope.Metrics = append(scope.Metrics, &metricpb.Metric{
+			Name: name,
+			Data: &metricpb.Metric_Gauge{
+				Gauge: &metricpb.Gauge{DataPoints: points},
+			},
+		})
+	}
+
+	return proto.Marshal(req)
+}
+
+func splitNameAndLabels(labels []prompb.Label) (string, []*commonpb.KeyValue) {
+	var name string
+	attrs := make([]*commonpb.KeyValue, 0, len(labels))
+	for _, l := range labels {
+		if l.Name == "__name__" {
+			name = l.Value
+			continue
+		}
+		attrs = append(attrs, &commonpb.KeyValue{
+			Key:   l.Name,
+			Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: l.Value}},
+		})
+	}
+	return name, attrs
+}